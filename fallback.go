@@ -0,0 +1,154 @@
+package nblogger
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Condition classifies the errors a Fallback applies to.
+type Condition int
+
+const (
+	OnDiskFull Condition = 1 << iota
+	OnPermissionDenied
+	OnFDExhaustion
+	OnOtherError
+	OnAnyError = OnDiskFull | OnPermissionDenied | OnFDExhaustion | OnOtherError
+)
+
+const fallbackRetryInterval = 10 * time.Second
+
+// Fallback is one step of the degradation ladder used when the log file
+// cannot be opened or written. Dir keeps the file name under another
+// directory, Writer sends entries to an arbitrary writer, and a Fallback
+// with neither set drops entries.
+type Fallback struct {
+	When   Condition
+	Dir    string
+	Writer io.Writer
+}
+
+func FallbackDir(dir string, when Condition) Fallback {
+	return Fallback{When: when, Dir: dir}
+}
+
+func FallbackStderr(when Condition) Fallback {
+	return Fallback{When: when, Writer: os.Stderr}
+}
+
+func FallbackDrop(when Condition) Fallback {
+	return Fallback{When: when}
+}
+
+// WithFallbacks declares what happens when the log file fails. The first
+// fallback matching the error's Condition is used, and the primary file is
+// retried every 10 seconds. Without fallbacks NewLogger fails if the file
+// cannot be opened, and entries that cannot be written are lost.
+func WithFallbacks(fallbacks ...Fallback) Option {
+	return func(logger *BasicLogger) {
+		logger.fallbacks = append(logger.fallbacks, fallbacks...)
+	}
+}
+
+func (fallback Fallback) String() string {
+	switch {
+	case fallback.Dir != "":
+		return fallback.Dir
+	case fallback.Writer == os.Stderr:
+		return "stderr"
+	case fallback.Writer == os.Stdout:
+		return "stdout"
+	case fallback.Writer != nil:
+		return "writer"
+	default:
+		return "drop"
+	}
+}
+
+func errorCondition(err error) Condition {
+	switch {
+	case isDiskFull(err):
+		return OnDiskFull
+	case isFDExhaustion(err):
+		return OnFDExhaustion
+	case os.IsPermission(err) || isReadOnly(err):
+		return OnPermissionDenied
+	default:
+		return OnOtherError
+	}
+}
+
+// degrade switches to the first fallback after index from that handles err
+// and can be activated, returning false when there is none.
+func (w *fileWriter) degrade(err error, from int) bool {
+	condition := errorCondition(err)
+	for i := from + 1; i < len(w.fallbacks); i++ {
+		fallback := w.fallbacks[i]
+		if fallback.When&condition == 0 {
+			continue
+		}
+
+		var secondary *os.File
+		out := io.Discard
+		if fallback.Dir != "" {
+			var openErr error
+			secondary, openErr = os.OpenFile(filepath.Join(fallback.Dir, filepath.Base(w.path)), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
+			if openErr != nil {
+				continue
+			}
+			out = secondary
+		} else if fallback.Writer != nil {
+			out = fallback.Writer
+		}
+
+		w.closeFallback()
+		w.degraded = i
+		w.fallback = out
+		w.secondary = secondary
+		w.retryAt = time.Now().Add(fallbackRetryInterval)
+		w.diagnose(Warn, "writing to %s failed (%v), falling back to %s", w.path, err, fallback)
+		return true
+	}
+
+	return false
+}
+
+// retry gives the primary file another chance while degraded.
+func (w *fileWriter) retry(p []byte) (int, error) {
+	w.retryAt = time.Now().Add(fallbackRetryInterval)
+	if w.file == nil && w.open() != nil {
+		return w.writeFallback(p)
+	}
+
+	written, err := w.write(p)
+	if err != nil {
+		n, err := w.writeFallback(p[written:])
+		return written + n, err
+	}
+
+	w.closeFallback()
+	w.degraded = -1
+	w.diagnose(Info, "resuming writes to %s", w.path)
+	return written, nil
+}
+
+func (w *fileWriter) writeFallback(p []byte) (int, error) {
+	written := 0
+	for {
+		n, err := w.fallback.Write(p[written:])
+		written += n
+		if err == nil || !w.degrade(err, w.degraded) {
+			return written, err
+		}
+	}
+}
+
+func (w *fileWriter) closeFallback() {
+	if w.secondary != nil {
+		w.secondary.Close()
+		w.secondary = nil
+	}
+	w.fallback = nil
+}
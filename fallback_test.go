@@ -0,0 +1,63 @@
+package nblogger
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFallbackOnOpen(t *testing.T) {
+	dir := t.TempDir()
+	logger, err := NewLogger(filepath.Join(dir, "missing", "app.log"), Info, bufferSize, LstdFlags,
+		WithFallbacks(FallbackDrop(OnDiskFull), FallbackDir(dir, OnAnyError)))
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	logger.Info("degraded")
+	logger.Close()
+
+	data, err := os.ReadFile(filepath.Join(dir, "app.log"))
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	content := string(data)
+	if !strings.Contains(content, "falling back to "+dir) || !strings.Contains(content, "degraded") {
+		t.Fatalf("unexpected content: %q", content)
+	}
+}
+
+func TestFallbackOnDiskFull(t *testing.T) {
+	if _, err := os.Stat("/dev/full"); err != nil {
+		t.Skip("/dev/full is not available")
+	}
+
+	dir := t.TempDir()
+	logger, err := NewLogger("/dev/full", Info, bufferSize, LstdFlags|Lblocking,
+		WithFallbacks(FallbackDir(dir, OnDiskFull)))
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	logger.Info("first")
+	logger.Info("second")
+	logger.Close()
+
+	data, err := os.ReadFile(filepath.Join(dir, "full"))
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	content := string(data)
+	if !strings.Contains(content, "first") || !strings.Contains(content, "second") {
+		t.Fatalf("unexpected content: %q", content)
+	}
+}
+
+func TestNoFallback(t *testing.T) {
+	_, err := NewLogger(filepath.Join(t.TempDir(), "missing", "app.log"), Info, bufferSize, LstdFlags,
+		WithFallbacks(FallbackStderr(OnDiskFull)))
+	if err == nil {
+		t.Fatalf("expected error")
+	}
+}
@@ -0,0 +1,149 @@
+package nblogger
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"unicode/utf8"
+)
+
+// Field is a key/value pair attached to an entry. A Field can also be
+// passed directly among the kv arguments of the KV methods.
+type Field struct {
+	Key   string
+	Value any
+}
+
+const badKey = "!BADKEY"
+
+// kvFields appends alternating key/value arguments to fields. A value
+// without a string key is kept under badKey rather than dropped.
+func kvFields(fields []Field, kv []any) []Field {
+	for i := 0; i < len(kv); {
+		switch key := kv[i].(type) {
+		case Field:
+			fields = append(fields, key)
+			i++
+		case string:
+			if i+1 < len(kv) {
+				fields = append(fields, Field{Key: key, Value: kv[i+1]})
+				i += 2
+			} else {
+				fields = append(fields, Field{Key: badKey, Value: key})
+				i++
+			}
+		default:
+			fields = append(fields, Field{Key: badKey, Value: key})
+			i++
+		}
+	}
+	return fields
+}
+
+func mapFields(fields []Field, m map[string]any) []Field {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		fields = append(fields, Field{Key: key, Value: m[key]})
+	}
+	return fields
+}
+
+func appendTextFields(buf *[]byte, fields []Field) {
+	for _, field := range fields {
+		*buf = append(*buf, ' ')
+		appendTextValue(buf, field.Key)
+		*buf = append(*buf, '=')
+		switch v := field.Value.(type) {
+		case string:
+			appendTextValue(buf, v)
+		case error:
+			appendTextValue(buf, v.Error())
+		default:
+			appendTextValue(buf, fmt.Sprint(v))
+		}
+	}
+}
+
+func appendTextValue(buf *[]byte, s string) {
+	if needsQuote(s) {
+		*buf = strconv.AppendQuote(*buf, s)
+	} else {
+		*buf = append(*buf, s...)
+	}
+}
+
+func needsQuote(s string) bool {
+	if len(s) == 0 {
+		return true
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c <= ' ' || c == '=' || c == '"' || c == 0x7f || c >= utf8.RuneSelf {
+			return true
+		}
+	}
+	return false
+}
+
+// childLogger shares level, output and queue with its base logger and adds
+// its own fields to every entry.
+type childLogger struct {
+	base   *BasicLogger
+	fields []Field
+}
+
+func (child *childLogger) with(kv []any) []Field {
+	fields := make([]Field, 0, len(child.fields)+len(kv)/2)
+	fields = append(fields, child.fields...)
+	return kvFields(fields, kv)
+}
+
+func (child *childLogger) Trace(format string, v ...any) {
+	child.base.logging(Trace, child.fields, format, v...)
+}
+func (child *childLogger) Debug(format string, v ...any) {
+	child.base.logging(Debug, child.fields, format, v...)
+}
+func (child *childLogger) Info(format string, v ...any) {
+	child.base.logging(Info, child.fields, format, v...)
+}
+func (child *childLogger) Warn(format string, v ...any) {
+	child.base.logging(Warn, child.fields, format, v...)
+}
+func (child *childLogger) Error(format string, v ...any) {
+	child.base.logging(Error, child.fields, format, v...)
+}
+func (child *childLogger) TraceKV(msg string, kv ...any) {
+	child.base.logging(Trace, child.with(kv), "%s", msg)
+}
+func (child *childLogger) DebugKV(msg string, kv ...any) {
+	child.base.logging(Debug, child.with(kv), "%s", msg)
+}
+func (child *childLogger) InfoKV(msg string, kv ...any) {
+	child.base.logging(Info, child.with(kv), "%s", msg)
+}
+func (child *childLogger) WarnKV(msg string, kv ...any) {
+	child.base.logging(Warn, child.with(kv), "%s", msg)
+}
+func (child *childLogger) ErrorKV(msg string, kv ...any) {
+	child.base.logging(Error, child.with(kv), "%s", msg)
+}
+func (child *childLogger) WithFields(fields map[string]any) Logger {
+	merged := make([]Field, 0, len(child.fields)+len(fields))
+	merged = append(merged, child.fields...)
+	return &childLogger{base: child.base, fields: mapFields(merged, fields)}
+}
+func (child *childLogger) SetLogLevel(level int) {
+	child.base.SetLogLevel(level)
+}
+func (child *childLogger) GetLogLevel() int {
+	return child.base.GetLogLevel()
+}
+func (child *childLogger) Close() {
+	child.base.Close()
+}
@@ -0,0 +1,168 @@
+package nblogger
+
+import (
+	"errors"
+	"io"
+	"os"
+	"time"
+)
+
+var ErrLocked = errors.New("log file is locked by another writer")
+
+type fileWriter struct {
+	path      string
+	notify    func(level int, format string, v ...any)
+	file      *os.File
+	lock      *os.File
+	nfs       *NFSConfig
+	opened    time.Time
+	synced    time.Time
+	unsynced  bool
+	fallbacks []Fallback
+	degraded  int
+	fallback  io.Writer
+	secondary *os.File
+	retryAt   time.Time
+}
+
+func (w *fileWriter) init() error {
+	w.degraded = -1
+
+	if w.nfs != nil {
+		// O_APPEND alone is not atomic across NFS clients, so make sure we
+		// are the only writer before touching the file.
+		lock, err := lockFile(w.path + ".lock")
+		if err != nil {
+			return err
+		}
+		w.lock = lock
+	}
+
+	if err := w.open(); err != nil && !w.degrade(err, -1) {
+		w.Close()
+		return err
+	}
+	w.synced = w.opened
+
+	return nil
+}
+
+func (w *fileWriter) open() error {
+	file, err := os.OpenFile(w.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
+	if err != nil {
+		return err
+	}
+	w.file = file
+	w.opened = time.Now()
+	return nil
+}
+
+func (w *fileWriter) reopen() error {
+	if w.file != nil {
+		if w.unsynced {
+			w.file.Sync()
+			w.unsynced = false
+		}
+		w.file.Close()
+		w.file = nil
+	}
+	return w.open()
+}
+
+func (w *fileWriter) Write(p []byte) (int, error) {
+	if w.degraded >= 0 {
+		if time.Now().Before(w.retryAt) {
+			return w.writeFallback(p)
+		}
+		return w.retry(p)
+	}
+
+	if w.nfs != nil && w.nfs.ReopenInterval > 0 && time.Since(w.opened) >= w.nfs.ReopenInterval {
+		w.reopen()
+	}
+
+	written, err := w.write(p)
+	if err != nil && isInvalidated(err) {
+		// The descriptor no longer refers to anything usable, which is what
+		// a process sees after a checkpoint/restore onto another host.
+		if reopenErr := w.reopen(); reopenErr == nil {
+			w.diagnose(Warn, "file descriptor for %s was invalidated (%v), reopened", w.path, err)
+			var n int
+			n, err = w.write(p[written:])
+			written += n
+		} else {
+			err = reopenErr
+		}
+	}
+
+	if err != nil && w.degrade(err, -1) {
+		n, err := w.writeFallback(p[written:])
+		return written + n, err
+	}
+
+	if w.nfs != nil && written > 0 {
+		w.unsynced = true
+		if w.nfs.SyncInterval > 0 && time.Since(w.synced) >= w.nfs.SyncInterval {
+			w.sync()
+		}
+	}
+
+	return written, err
+}
+
+func (w *fileWriter) write(p []byte) (int, error) {
+	if w.nfs == nil {
+		if w.file == nil {
+			return 0, os.ErrClosed
+		}
+		return w.file.Write(p)
+	}
+
+	written := 0
+	var err error
+	for attempt := 0; ; attempt++ {
+		if w.file == nil {
+			err = os.ErrClosed
+		} else {
+			var n int
+			n, err = w.file.Write(p[written:])
+			written += n
+		}
+		if err == nil || attempt >= w.nfs.Retries || !isStale(err) {
+			break
+		}
+		time.Sleep(w.nfs.RetryDelay)
+		w.reopen()
+	}
+
+	return written, err
+}
+
+func (w *fileWriter) diagnose(level int, format string, v ...any) {
+	if w.notify != nil {
+		w.notify(level, format, v...)
+	}
+}
+
+func (w *fileWriter) sync() error {
+	w.synced = time.Now()
+	w.unsynced = false
+	return w.file.Sync()
+}
+
+func (w *fileWriter) Close() error {
+	var err error
+	if w.file != nil {
+		if w.unsynced {
+			w.sync()
+		}
+		err = w.file.Close()
+		w.file = nil
+	}
+	w.closeFallback()
+	if w.lock != nil {
+		unlockFile(w.lock)
+		w.lock = nil
+	}
+	return err
+}
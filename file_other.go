@@ -0,0 +1,36 @@
+//go:build !(linux || darwin || freebsd || netbsd || openbsd || dragonfly)
+
+package nblogger
+
+import (
+	"errors"
+	"os"
+)
+
+func lockFile(path string) (*os.File, error) {
+	return os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0666)
+}
+
+func unlockFile(file *os.File) {
+	file.Close()
+}
+
+func isStale(err error) bool {
+	return false
+}
+
+func isInvalidated(err error) bool {
+	return errors.Is(err, os.ErrClosed)
+}
+
+func isDiskFull(err error) bool {
+	return false
+}
+
+func isFDExhaustion(err error) bool {
+	return false
+}
+
+func isReadOnly(err error) bool {
+	return false
+}
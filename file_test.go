@@ -0,0 +1,85 @@
+package nblogger
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestNFSSingleWriter(t *testing.T) {
+	logger, err := NewLogger(logFilePath, Info, bufferSize, LstdFlags|Lnfs)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	defer os.Remove(logFilePath + ".lock")
+	defer os.Remove(logFilePath)
+
+	_, err = NewLogger(logFilePath, Info, bufferSize, LstdFlags|Lnfs)
+	if !errors.Is(err, ErrLocked) {
+		t.Fatalf("expected ErrLocked, got %v", err)
+	}
+
+	logger.Info("hello %s", "nfs")
+	logger.Close()
+
+	data, err := os.ReadFile(logFilePath)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	if !strings.Contains(string(data), "hello nfs") {
+		t.Fatalf("unexpected content: %q", data)
+	}
+
+	logger, err = NewLogger(logFilePath, Info, bufferSize, LstdFlags|Lnfs)
+	if err != nil {
+		t.Fatalf("lock not released: %v", err)
+	}
+	logger.Close()
+}
+
+func TestNFSReopen(t *testing.T) {
+	config := DefaultNFSConfig
+	config.ReopenInterval = 1
+	logger, err := NewLogger(logFilePath, Info, bufferSize, LstdFlags|Lblocking, WithNFSConfig(config))
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	defer os.Remove(logFilePath + ".lock")
+	defer os.Remove(logFilePath)
+
+	logger.Info("first")
+	os.Remove(logFilePath)
+	logger.Info("second")
+	logger.Close()
+
+	data, err := os.ReadFile(logFilePath)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	if !strings.Contains(string(data), "second") {
+		t.Fatalf("unexpected content: %q", data)
+	}
+}
+
+func TestReopenInvalidatedFile(t *testing.T) {
+	logger, err := NewLogger(logFilePath, Info, bufferSize, LstdFlags|Lshortfile|Lblocking)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	defer os.Remove(logFilePath)
+
+	logger.Info("before")
+	logger.(*BasicLogger).file.file.Close()
+	logger.Info("after")
+	logger.Close()
+
+	data, err := os.ReadFile(logFilePath)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	content := string(data)
+	if !strings.Contains(content, "after") || !strings.Contains(content, "nb-logger: file descriptor") {
+		t.Fatalf("unexpected content: %q", content)
+	}
+}
@@ -0,0 +1,155 @@
+package nblogger
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+	"unicode/utf8"
+)
+
+var levelNameMap = map[int]string{
+	Trace: "trace",
+	Debug: "debug",
+	Info:  "info",
+	Warn:  "warn",
+	Error: "error",
+}
+
+const hex = "0123456789abcdef"
+
+func (logger *BasicLogger) formatJSON(buf *[]byte, message *logMessage, s string) {
+	t := message.time
+	if logger.flags&LUTC != 0 {
+		t = t.UTC()
+	}
+
+	*buf = append(*buf, `{"level":"`...)
+	*buf = append(*buf, levelNameMap[message.level]...)
+	*buf = append(*buf, `","ts":"`...)
+	*buf = t.AppendFormat(*buf, time.RFC3339Nano)
+	*buf = append(*buf, '"')
+
+	if logger.flags&(Lshortfile|Llongfile) != 0 && message.file != "" {
+		var caller []byte
+		logger.formatCaller(&caller, message.file, message.line)
+		*buf = append(*buf, `,"caller":`...)
+		appendJSONString(buf, string(caller))
+	}
+
+	if len(s) > 0 && s[len(s)-1] == '\n' {
+		s = s[:len(s)-1]
+	}
+	*buf = append(*buf, `,"msg":`...)
+	appendJSONString(buf, s)
+	for _, field := range message.fields {
+		*buf = append(*buf, ',')
+		appendJSONString(buf, field.Key)
+		*buf = append(*buf, ':')
+		appendJSONValue(buf, field.Value)
+	}
+	*buf = append(*buf, "}\n"...)
+}
+
+func appendJSONValue(buf *[]byte, value any) {
+	switch v := value.(type) {
+	case nil:
+		*buf = append(*buf, "null"...)
+	case string:
+		appendJSONString(buf, v)
+	case bool:
+		*buf = strconv.AppendBool(*buf, v)
+	case int:
+		*buf = strconv.AppendInt(*buf, int64(v), 10)
+	case int8:
+		*buf = strconv.AppendInt(*buf, int64(v), 10)
+	case int16:
+		*buf = strconv.AppendInt(*buf, int64(v), 10)
+	case int32:
+		*buf = strconv.AppendInt(*buf, int64(v), 10)
+	case int64:
+		*buf = strconv.AppendInt(*buf, v, 10)
+	case uint:
+		*buf = strconv.AppendUint(*buf, uint64(v), 10)
+	case uint8:
+		*buf = strconv.AppendUint(*buf, uint64(v), 10)
+	case uint16:
+		*buf = strconv.AppendUint(*buf, uint64(v), 10)
+	case uint32:
+		*buf = strconv.AppendUint(*buf, uint64(v), 10)
+	case uint64:
+		*buf = strconv.AppendUint(*buf, v, 10)
+	case float32:
+		appendJSONFloat(buf, float64(v), 32)
+	case float64:
+		appendJSONFloat(buf, v, 64)
+	case time.Time:
+		*buf = append(*buf, '"')
+		*buf = v.AppendFormat(*buf, time.RFC3339Nano)
+		*buf = append(*buf, '"')
+	case time.Duration:
+		appendJSONString(buf, v.String())
+	case error:
+		appendJSONString(buf, v.Error())
+	case fmt.Stringer:
+		appendJSONString(buf, v.String())
+	default:
+		data, err := json.Marshal(v)
+		if err != nil {
+			appendJSONString(buf, fmt.Sprint(v))
+			return
+		}
+		*buf = append(*buf, data...)
+	}
+}
+
+func appendJSONFloat(buf *[]byte, f float64, bits int) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		appendJSONString(buf, strconv.FormatFloat(f, 'g', -1, bits))
+		return
+	}
+	*buf = strconv.AppendFloat(*buf, f, 'g', -1, bits)
+}
+
+func appendJSONString(buf *[]byte, s string) {
+	*buf = append(*buf, '"')
+	start := 0
+	for i := 0; i < len(s); {
+		c := s[i]
+		if c < utf8.RuneSelf {
+			if c >= 0x20 && c != '"' && c != '\\' {
+				i++
+				continue
+			}
+			*buf = append(*buf, s[start:i]...)
+			switch c {
+			case '"', '\\':
+				*buf = append(*buf, '\\', c)
+			case '\n':
+				*buf = append(*buf, '\\', 'n')
+			case '\r':
+				*buf = append(*buf, '\\', 'r')
+			case '\t':
+				*buf = append(*buf, '\\', 't')
+			default:
+				*buf = append(*buf, '\\', 'u', '0', '0', hex[c>>4], hex[c&0xf])
+			}
+			i++
+			start = i
+			continue
+		}
+
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size == 1 {
+			*buf = append(*buf, s[start:i]...)
+			*buf = append(*buf, "\ufffd"...)
+			i += size
+			start = i
+			continue
+		}
+		i += size
+	}
+	*buf = append(*buf, s[start:]...)
+	*buf = append(*buf, '"')
+}
@@ -0,0 +1,54 @@
+package nblogger
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestJSONOutput(t *testing.T) {
+	logger, err := NewLogger(logFilePath, Debug, bufferSize, Lshortfile|Ljson)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	defer os.Remove(logFilePath)
+
+	logger.Trace("hidden")
+	logger.Info("quote \" tab \t newline \n end")
+	logger.Error("invalid \xff utf8\n")
+	logger.Close()
+
+	file, err := os.Open(logFilePath)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	defer file.Close()
+
+	var lines []map[string]string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var line map[string]string
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			t.Fatalf("invalid json %q: %v", scanner.Text(), err)
+		}
+		lines = append(lines, line)
+	}
+
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 lines, got %d", len(lines))
+	}
+	if lines[0]["level"] != "info" || lines[0]["msg"] != "quote \" tab \t newline \n end" {
+		t.Fatalf("unexpected entry: %v", lines[0])
+	}
+	if !strings.HasPrefix(lines[0]["caller"], "json_test.go:") {
+		t.Fatalf("unexpected caller: %v", lines[0]["caller"])
+	}
+	if lines[1]["level"] != "error" || lines[1]["msg"] != "invalid � utf8" {
+		t.Fatalf("unexpected entry: %v", lines[1])
+	}
+	if lines[1]["ts"] == "" {
+		t.Fatalf("missing ts: %v", lines[1])
+	}
+}
@@ -1,7 +1,6 @@
 package nblogger
 
 import (
-	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -18,6 +17,12 @@ type Logger interface {
 	Info(format string, v ...any)
 	Warn(format string, v ...any)
 	Error(format string, v ...any)
+	TraceKV(msg string, kv ...any)
+	DebugKV(msg string, kv ...any)
+	InfoKV(msg string, kv ...any)
+	WarnKV(msg string, kv ...any)
+	ErrorKV(msg string, kv ...any)
+	WithFields(fields map[string]any) Logger
 	SetLogLevel(level int)
 	GetLogLevel() int
 	Close()
@@ -40,6 +45,8 @@ const (
 	LUTC
 	Lblocking
 	Lstdout
+	Lnfs
+	Ljson
 	LstdFlags = Ldate | Ltime
 )
 
@@ -50,18 +57,26 @@ const (
 
 type logMessage struct {
 	cmd    int
-	header []byte
+	level  int
+	time   time.Time
+	file   string
+	line   int
 	format string
 	v      []any
+	fields []Field
 }
 
 type BasicLogger struct {
 	level          int
 	flags          int
+	file           *fileWriter
+	nfs            *NFSConfig
+	fallbacks      []Fallback
 	writer         io.Writer
 	zq             *zenq.ZenQ[logMessage]
 	logIndex       int
 	logMessagePool int
+	diagnostics    []logMessage
 	lock           sync.Mutex
 	wg             sync.WaitGroup
 }
@@ -77,28 +92,41 @@ var levelStringMap = map[int]string{
 func init() {
 }
 
-func NewLogger(path string, level int, bufferSize int, flags int) (Logger, error) {
-	logFile, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
-	if err != nil {
-		fmt.Printf("err: %v", err)
-		return nil, errors.New("file creation fail")
+func NewLogger(path string, level int, bufferSize int, flags int, opts ...Option) (Logger, error) {
+	logger := &BasicLogger{
+		level: level,
+		flags: flags,
+		zq:    zenq.New[logMessage](uint32(bufferSize)),
+		lock:  sync.Mutex{},
+		wg:    sync.WaitGroup{},
 	}
 
-	var writer io.Writer
-	if flags&Lstdout != 0 {
-		writer = io.MultiWriter(logFile, os.Stdout)
-	} else {
-		writer = io.MultiWriter(logFile)
+	for _, opt := range opts {
+		opt(logger)
 	}
 
-	logger := &BasicLogger{
-		level:  level,
-		flags:  flags,
-		writer: writer,
-		zq:     zenq.New[logMessage](uint32(bufferSize)),
-		lock:   sync.Mutex{},
-		wg:     sync.WaitGroup{},
+	if logger.flags&Lnfs != 0 && logger.nfs == nil {
+		config := DefaultNFSConfig
+		logger.nfs = &config
+	}
+
+	logFile := &fileWriter{
+		path:      path,
+		notify:    logger.diagnose,
+		nfs:       logger.nfs,
+		fallbacks: logger.fallbacks,
+	}
+	if err := logFile.init(); err != nil {
+		return nil, fmt.Errorf("file creation fail: %w", err)
 	}
+	logger.file = logFile
+
+	if logger.flags&Lstdout != 0 {
+		logger.writer = io.MultiWriter(logFile, os.Stdout)
+	} else {
+		logger.writer = io.MultiWriter(logFile)
+	}
+	logger.flushDiagnostics()
 
 	if logger.flags&Lblocking == 0 {
 		logger.wg.Add(1)
@@ -154,79 +182,121 @@ func (logger *BasicLogger) formatHeader(buf *[]byte, level int, t time.Time, fil
 			*buf = append(*buf, ' ')
 		}
 	}
-	if logger.flags&(Lshortfile|Llongfile) != 0 {
-		if logger.flags&Lshortfile != 0 {
-			short := file
-			for i := len(file) - 1; i > 0; i-- {
-				if file[i] == '/' {
-					short = file[i+1:]
-					break
-				}
+	if logger.flags&(Lshortfile|Llongfile) != 0 && file != "" {
+		logger.formatCaller(buf, file, line)
+		*buf = append(*buf, ": "...)
+	}
+}
+
+func (logger *BasicLogger) formatCaller(buf *[]byte, file string, line int) {
+	if logger.flags&Lshortfile != 0 {
+		short := file
+		for i := len(file) - 1; i > 0; i-- {
+			if file[i] == '/' {
+				short = file[i+1:]
+				break
 			}
-			file = short
 		}
-		*buf = append(*buf, file...)
-		*buf = append(*buf, ':')
-		itoa(buf, line, -1)
-		*buf = append(*buf, ": "...)
+		file = short
 	}
+	*buf = append(*buf, file...)
+	*buf = append(*buf, ':')
+	itoa(buf, line, -1)
 }
 
-func (logger *BasicLogger) logging(level int, format string, v ...any) {
+func (logger *BasicLogger) encode(buf *[]byte, message *logMessage) {
+	s := fmt.Sprintf(message.format, message.v...)
+	if logger.flags&Ljson != 0 {
+		logger.formatJSON(buf, message, s)
+		return
+	}
+
+	logger.formatHeader(buf, message.level, message.time, message.file, message.line)
+	*buf = append(*buf, s...)
+	if len(message.fields) > 0 {
+		if len(s) > 0 && s[len(s)-1] == '\n' {
+			*buf = (*buf)[:len(*buf)-1]
+		}
+		appendTextFields(buf, message.fields)
+		s = ""
+	}
+	if len(s) == 0 || s[len(s)-1] != '\n' {
+		*buf = append(*buf, '\n')
+	}
+}
+
+func (logger *BasicLogger) logging(level int, fields []Field, format string, v ...any) {
 	if logger.level > level {
 		return
 	}
 
-	now := time.Now()
-	logger.lock.Lock()
-	defer logger.lock.Unlock()
-	var file string
-	var line int
+	message := logMessage{
+		cmd:    write,
+		level:  level,
+		time:   time.Now(),
+		format: format,
+		v:      v,
+		fields: fields,
+	}
 
 	if logger.flags&(Lshortfile|Llongfile) != 0 {
-		logger.lock.Unlock()
 		var ok bool
-		_, file, line, ok = runtime.Caller(2)
+		_, message.file, message.line, ok = runtime.Caller(2)
 		if !ok {
-			file = "unknown"
-			line = 0
+			message.file = "unknown"
+			message.line = 0
 		}
-		logger.lock.Lock()
 	}
 
-	var header []byte
-	logger.formatHeader(&header, level, now, file, line)
+	logger.lock.Lock()
+	defer logger.lock.Unlock()
 
 	if logger.flags&Lblocking == 0 {
-		message := logMessage{
-			cmd:    write,
-			header: header,
-			format: format,
-			v:      v,
-		}
-
 		logger.zq.Write(message)
 	} else {
-		s := fmt.Sprintf(format, v...)
-		header = append(header, s...)
-		if len(s) == 0 || s[len(s)-1] != '\n' {
-			header = append(header, '\n')
-		}
-		logger.writer.Write(header)
+		logger.write(&message)
+	}
+}
+
+func (logger *BasicLogger) write(message *logMessage) {
+	var buf []byte
+	logger.encode(&buf, message)
+	logger.writer.Write(buf)
+	logger.flushDiagnostics()
+}
+
+func (logger *BasicLogger) flushDiagnostics() {
+	for len(logger.diagnostics) > 0 {
+		diagnostic := logger.diagnostics[0]
+		logger.diagnostics = logger.diagnostics[1:]
+		var buf []byte
+		logger.encode(&buf, &diagnostic)
+		logger.writer.Write(buf)
+	}
+}
+
+// diagnose records an entry about the logger itself. It must only be called
+// from the goroutine that is currently writing, and the entry is emitted
+// right after the entry being written.
+func (logger *BasicLogger) diagnose(level int, format string, v ...any) {
+	if logger.level > level {
+		return
 	}
+
+	logger.diagnostics = append(logger.diagnostics, logMessage{
+		cmd:    write,
+		level:  level,
+		time:   time.Now(),
+		format: "nb-logger: " + format,
+		v:      v,
+	})
 }
 
 func (logger *BasicLogger) server() {
 	defer logger.wg.Done()
 	for {
 		if message, err := logger.zq.Read(); err && message.cmd == write {
-			s := fmt.Sprintf(message.format, message.v...)
-			buf := message.header
-			buf = append(buf, s...)
-			if len(s) == 0 || s[len(s)-1] != '\n' {
-				buf = append(buf, '\n')
-			}
-			logger.writer.Write(buf)
+			logger.write(&message)
 		} else {
 			break
 		}
@@ -234,19 +304,37 @@ func (logger *BasicLogger) server() {
 }
 
 func (logger *BasicLogger) Trace(format string, v ...any) {
-	logger.logging(Trace, format, v...)
+	logger.logging(Trace, nil, format, v...)
 }
 func (logger *BasicLogger) Debug(format string, v ...any) {
-	logger.logging(Debug, format, v...)
+	logger.logging(Debug, nil, format, v...)
 }
 func (logger *BasicLogger) Info(format string, v ...any) {
-	logger.logging(Info, format, v...)
+	logger.logging(Info, nil, format, v...)
 }
 func (logger *BasicLogger) Warn(format string, v ...any) {
-	logger.logging(Warn, format, v...)
+	logger.logging(Warn, nil, format, v...)
 }
 func (logger *BasicLogger) Error(format string, v ...any) {
-	logger.logging(Error, format, v...)
+	logger.logging(Error, nil, format, v...)
+}
+func (logger *BasicLogger) TraceKV(msg string, kv ...any) {
+	logger.logging(Trace, kvFields(nil, kv), "%s", msg)
+}
+func (logger *BasicLogger) DebugKV(msg string, kv ...any) {
+	logger.logging(Debug, kvFields(nil, kv), "%s", msg)
+}
+func (logger *BasicLogger) InfoKV(msg string, kv ...any) {
+	logger.logging(Info, kvFields(nil, kv), "%s", msg)
+}
+func (logger *BasicLogger) WarnKV(msg string, kv ...any) {
+	logger.logging(Warn, kvFields(nil, kv), "%s", msg)
+}
+func (logger *BasicLogger) ErrorKV(msg string, kv ...any) {
+	logger.logging(Error, kvFields(nil, kv), "%s", msg)
+}
+func (logger *BasicLogger) WithFields(fields map[string]any) Logger {
+	return &childLogger{base: logger, fields: mapFields(nil, fields)}
 }
 func (logger *BasicLogger) SetLogLevel(level int) {
 	logger.level = level
@@ -258,4 +346,5 @@ func (logger *BasicLogger) GetLogLevel() int {
 func (logger *BasicLogger) Close() {
 	logger.zq.Write(logMessage{cmd: exit})
 	logger.wg.Wait()
+	logger.file.Close()
 }
@@ -0,0 +1,35 @@
+package nblogger
+
+import "time"
+
+// Option customizes a logger at construction time.
+type Option func(logger *BasicLogger)
+
+// NFSConfig tunes the Lnfs mode used for logs that live on network filesystems.
+type NFSConfig struct {
+	// ReopenInterval reopens the file periodically so a failed-over server
+	// hands out a fresh handle. Zero disables periodic reopen.
+	ReopenInterval time.Duration
+	// SyncInterval batches fsync calls: the file is synced at most once per
+	// interval while writes are flowing, and always on Close.
+	SyncInterval time.Duration
+	// Retries is how many times a write failing with ESTALE is retried
+	// after reopening the file.
+	Retries    int
+	RetryDelay time.Duration
+}
+
+var DefaultNFSConfig = NFSConfig{
+	ReopenInterval: 30 * time.Second,
+	SyncInterval:   time.Second,
+	Retries:        3,
+	RetryDelay:     100 * time.Millisecond,
+}
+
+// WithNFSConfig enables the Lnfs mode with the given settings.
+func WithNFSConfig(config NFSConfig) Option {
+	return func(logger *BasicLogger) {
+		logger.flags |= Lnfs
+		logger.nfs = &config
+	}
+}